@@ -0,0 +1,112 @@
+#!/usr/bin/env python3
+#
+# Description: Expose Huawei Ascend NPU statistics from the npu-smi info table.
+#
+#   npu-smi info | ./npu_smi.py
+#
+# Each NPU is printed as a pair of table rows, the first with the NPU id,
+# name, health, power and temperature, the second with the chip id (and on
+# some cards the device id), bus id, AI core usage and memory usage:
+#
+#   | 0     910A           | OK            | 68.5        36                0    / 0           |
+#   | 0                    | 0000:C1:00.0  | 0           1011 / 15171      0    / 32768       |
+#
+# Cards with several chips repeat the NPU row for every chip. Values reported
+# as "NA" or "-" are not exported.
+
+import re
+import sys
+
+bus_id_re = re.compile(r'^[0-9A-Fa-f]{4}:[0-9A-Fa-f]{2}:[0-9A-Fa-f]{2}\.[0-9A-Fa-f]$')
+usage_re = re.compile(r'(\d+)\s*/\s*(\d+)')
+
+
+# Print metrics in Prometheus format.
+def print_prometheus(metric, help_text, values):
+    if not values:
+        return
+    print("# HELP npu_%s %s" % (metric, help_text))
+    print("# TYPE npu_%s gauge" % (metric))
+    for labels in sorted(values):
+        print("npu_%s{%s} %f" % (metric, labels, values[labels]))
+
+
+def to_float(value):
+    try:
+        return float(value)
+    except ValueError:
+        return None
+
+
+# Parse npu-smi info output into a dict of metric name to values.
+def parse_output(output):
+    metrics = {}
+    npu = None
+
+    def add(metric, value, labels):
+        if value is not None:
+            metrics.setdefault(metric, {})[labels] = value
+
+    for line in output.split('\n'):
+        if not line.startswith('|'):
+            continue
+        cells = [cell.strip() for cell in line.strip().strip('|').split('|')]
+        if len(cells) < 3:
+            continue
+        first = cells[0].split()
+        if not first or not first[0].isdigit():
+            # Header and version rows.
+            continue
+
+        if not bus_id_re.match(cells[1]):
+            # NPU row: power and temperature are carried over to its chips.
+            values = cells[2].split()
+            npu = {
+                'id': first[0],
+                'power': to_float(values[0]) if len(values) > 0 else None,
+                'temperature': to_float(values[1]) if len(values) > 1 else None,
+            }
+            continue
+        if npu is None:
+            continue
+
+        labels = 'npu="%s",chip="%s"' % (npu['id'], first[0])
+        if npu['power'] is not None:
+            add('power_usage_milliwatts', npu['power'] * 1000, labels)
+        add('temperature_celsius', npu['temperature'], labels)
+
+        values = cells[2].split()
+        if values:
+            add('duty_cycle', to_float(values[0]), labels)
+        usages = usage_re.findall(cells[2])
+        if len(usages) > 0:
+            add('memory_used_bytes', float(usages[0][0]) * 1024 * 1024, labels)
+            add('memory_total_bytes', float(usages[0][1]) * 1024 * 1024, labels)
+        if len(usages) > 1:
+            add('hbm_used_bytes', float(usages[1][0]) * 1024 * 1024, labels)
+            add('hbm_total_bytes', float(usages[1][1]) * 1024 * 1024, labels)
+
+    return metrics
+
+
+help_texts = {
+    'duty_cycle': 'AI core utilization in percent.',
+    'memory_used_bytes': 'Used NPU memory in bytes.',
+    'memory_total_bytes': 'Total NPU memory in bytes.',
+    'hbm_used_bytes': 'Used high bandwidth memory in bytes.',
+    'hbm_total_bytes': 'Total high bandwidth memory in bytes.',
+    'temperature_celsius': 'NPU temperature in degrees celsius.',
+    'power_usage_milliwatts': 'NPU power usage in milliwatts.',
+}
+
+
+# Main function
+def main(argv):
+    metrics = parse_output(sys.stdin.read())
+    for metric in sorted(metrics):
+        print_prometheus(metric, help_texts[metric], metrics[metric])
+
+
+# Go go go!
+if __name__ == "__main__":
+    main(sys.argv[1:])
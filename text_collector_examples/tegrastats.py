@@ -0,0 +1,133 @@
+#!/usr/bin/env python3
+#
+# Description: Expose NVIDIA Jetson (Tegra) statistics from tegrastats.
+#
+# tegrastats prints one line of statistics per interval and never exits, so
+# feed a single line into this script:
+#
+#   tegrastats --interval 1000 | head -n 1 | ./tegrastats.py
+#
+# Tested against the output of JetPack 4.x (L4T R32) and JetPack 5.x
+# (L4T R35). The fields present depend on the JetPack version and board,
+# fields that are missing are simply not exported.
+
+import re
+import sys
+
+# Hardware engines reported either as a clock in MHz or as "off".
+engines = ['NVENC', 'NVDEC', 'NVJPG', 'NVJPG1', 'MSENC', 'VIC', 'OFA']
+
+# Thermal zones reporting this temperature have no sensor attached.
+absent_temperature = -256.0
+
+# RAM 2070/3956MB (lfb 80x4MB)
+ram_re = re.compile(r'^(\d+)/(\d+)MB$')
+# CPU [14%@102,3%@102,off,off]
+cpu_re = re.compile(r'^(\d+)%@(\d+)$')
+# EMC_FREQ 0%@1600, GR3D_FREQ 0%@76 or GR3D_FREQ 0%@[305,305]
+freq_re = re.compile(r'^(\d+)%@\[?(\d+)')
+# GPU@27C, thermal@27.25C
+thermal_re = re.compile(r'^(\w+)@(-?\d+(?:\.\d+)?)C$')
+# POM_5V_IN 1119/1119 or VDD_GPU_SOC 2787mW/2787mW
+rail_re = re.compile(r'^(\d+)(?:mW)?/(\d+)(?:mW)?$')
+
+
+# Print metrics in Prometheus format.
+def print_prometheus(metric, help_text, values):
+    if not values:
+        return
+    print("# HELP tegra_%s %s" % (metric, help_text))
+    print("# TYPE tegra_%s gauge" % (metric))
+    for labels in sorted(values, key=lambda l: l or ''):
+        if labels is None:
+            print("tegra_%s %f" % (metric, values[labels]))
+        else:
+            print("tegra_%s{%s} %f" % (metric, labels, values[labels]))
+
+
+# Parse a single tegrastats line into a dict of metric name to values.
+def parse_line(line):
+    metrics = {}
+
+    def add(metric, value, labels=None):
+        metrics.setdefault(metric, {})[labels] = value
+
+    fields = line.split()
+    for i, field in enumerate(fields):
+        value = fields[i + 1] if i + 1 < len(fields) else ''
+
+        if field in ('RAM', 'SWAP'):
+            match = ram_re.match(value)
+            if match:
+                name = field.lower()
+                add('%s_used_bytes' % name, float(match.group(1)) * 1024 * 1024)
+                add('%s_total_bytes' % name, float(match.group(2)) * 1024 * 1024)
+        elif field == 'CPU' and value.startswith('['):
+            for cpu, usage in enumerate(value.strip('[]').split(',')):
+                match = cpu_re.match(usage)
+                if not match:
+                    # Offline cores are reported as "off".
+                    continue
+                labels = 'cpu="%d"' % cpu
+                add('cpu_duty_cycle', float(match.group(1)), labels)
+                add('cpu_clock_hz', float(match.group(2)) * 1e6, labels)
+        elif field in ('EMC_FREQ', 'GR3D_FREQ', 'GR3D'):
+            match = freq_re.match(value)
+            if match:
+                name = 'emc' if field == 'EMC_FREQ' else 'gpu'
+                add('%s_duty_cycle' % name, float(match.group(1)))
+                add('%s_clock_hz' % name, float(match.group(2)) * 1e6)
+        elif field in engines:
+            if value == 'off':
+                add('%s_clock_hz' % field.lower(), 0.0)
+            elif value.isdigit():
+                add('%s_clock_hz' % field.lower(), float(value) * 1e6)
+        elif thermal_re.match(field):
+            match = thermal_re.match(field)
+            temperature = float(match.group(2))
+            if temperature != absent_temperature:
+                add('temperature_celsius', temperature, 'zone="%s"' % match.group(1))
+        elif re.match(r'^[A-Z][A-Z0-9_]+$', field) and rail_re.match(value):
+            match = rail_re.match(value)
+            labels = 'rail="%s"' % field
+            add('power_rail_milliwatts', float(match.group(1)), labels)
+            add('power_rail_average_milliwatts', float(match.group(2)), labels)
+
+    return metrics
+
+
+help_texts = {
+    'ram_used_bytes': 'Used RAM in bytes.',
+    'ram_total_bytes': 'Total RAM in bytes.',
+    'swap_used_bytes': 'Used swap in bytes.',
+    'swap_total_bytes': 'Total swap in bytes.',
+    'cpu_duty_cycle': 'CPU core utilization in percent.',
+    'cpu_clock_hz': 'CPU core clock in hertz.',
+    'emc_duty_cycle': 'External memory controller utilization in percent.',
+    'emc_clock_hz': 'External memory controller clock in hertz.',
+    'gpu_duty_cycle': 'GPU (GR3D) utilization in percent.',
+    'gpu_clock_hz': 'GPU (GR3D) clock in hertz.',
+    'temperature_celsius': 'Thermal zone temperature in degrees celsius.',
+    'power_rail_milliwatts': 'Current power rail consumption in milliwatts.',
+    'power_rail_average_milliwatts': 'Average power rail consumption in milliwatts.',
+}
+for engine in engines:
+    help_texts['%s_clock_hz' % engine.lower()] = \
+        '%s engine clock in hertz, 0 when the engine is off.' % engine
+
+
+# Main function
+def main(argv):
+    lines = [line for line in sys.stdin.read().split('\n') if line.strip()]
+    if not lines:
+        sys.exit("no tegrastats output on stdin")
+
+    # Only the most recent line is of interest.
+    metrics = parse_line(lines[-1])
+    for metric in sorted(metrics):
+        print_prometheus(metric, help_texts[metric], metrics[metric])
+
+
+# Go go go!
+if __name__ == "__main__":
+    main(sys.argv[1:])